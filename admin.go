@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// startAdminServer runs the admin HTTP server on config.AdminAddr until it
+// fails; the caller runs this in its own goroutine.
+func (s *ProxyServer) startAdminServer() {
+	s.infoLogger.Printf("Admin server listening on %s", s.config.AdminAddr)
+	if err := http.ListenAndServe(s.config.AdminAddr, s.adminMux()); err != nil {
+		s.errorLogger.Printf("Admin server stopped: %v", err)
+	}
+}
+
+// adminMux builds the admin HTTP handler exposing metrics, cache
+// inspection, and a health check. It's served on a separate listener
+// (config.AdminAddr) from the proxy's main data path, mirroring how
+// reverse-proxy tools like frp expose their own admin API.
+func (s *ProxyServer) adminMux() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/cache", s.handleCacheList)
+	mux.HandleFunc("/cache/purge", s.handleCachePurge)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	return mux
+}
+
+// handleMetrics renders the proxy's metrics in Prometheus text format.
+func (s *ProxyServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprint(w, s.metrics.registry.WriteTo())
+}
+
+// cacheEntryView is the JSON shape of one entry returned by /cache.
+type cacheEntryView struct {
+	Key        string  `json:"key"`
+	SizeBytes  int     `json:"size_bytes"`
+	AgeSeconds float64 `json:"age_seconds"`
+}
+
+// handleCacheList lists every cache entry's key, size, and age as JSON.
+func (s *ProxyServer) handleCacheList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	entries := s.cache.Entries()
+	views := make([]cacheEntryView, len(entries))
+	for i, e := range entries {
+		views[i] = cacheEntryView{Key: e.Key, SizeBytes: e.SizeBytes, AgeSeconds: e.Age.Seconds()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(views)
+}
+
+// handleCachePurge evicts a single entry identified by the "key" query
+// parameter, or the entire cache if key is omitted.
+func (s *ProxyServer) handleCachePurge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		s.cache.PurgeAll()
+		fmt.Fprintln(w, "purged entire cache")
+		return
+	}
+
+	if !s.cache.Purge(key) {
+		http.Error(w, "key not found", http.StatusNotFound)
+		return
+	}
+	fmt.Fprintf(w, "purged %s\n", key)
+}
+
+// handleHealthz reports liveness for load balancers and orchestrators.
+func (s *ProxyServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"sync"
 
 	"github.com/soumadeep-sarkar/multi-threaded-proxy-server/config"
 	"github.com/soumadeep-sarkar/multi-threaded-proxy-server/internal/cache"
@@ -15,6 +16,21 @@ type ProxyServer struct {
 	cache       *cache.LRUCache
 	config      *config.Config
 	semaphore   chan struct{}
+
+	// varyMu guards varyIndex, which remembers the Vary header last seen
+	// for a given primary cache key so lookups can fold the right request
+	// headers into the cache key before the origin is contacted again.
+	varyMu    sync.RWMutex
+	varyIndex map[string]string
+
+	// ProxyFunc selects the parent proxy to dial for a given request, or
+	// nil to go directly to the origin. It defaults to one derived from
+	// cfg's HTTPProxy/HTTPSProxy/NoProxy settings but is exported so
+	// callers can swap in a custom selection strategy.
+	ProxyFunc ProxyFunc
+
+	// metrics backs the admin /metrics endpoint.
+	metrics *serverMetrics
 }
 
 // NewProxyServer creates a new instance of the proxy server
@@ -22,11 +38,19 @@ func NewProxyServer(cfg *config.Config) *ProxyServer {
 	infoLogger := log.New(os.Stdout, "[INFO] ", log.Ldate|log.Ltime|log.Lshortfile)
 	errorLogger := log.New(os.Stderr, "[ERROR] ", log.Ldate|log.Ltime|log.Lshortfile)
 
-	return &ProxyServer{
+	s := &ProxyServer{
 		infoLogger:  infoLogger,
 		errorLogger: errorLogger,
 		cache:       cache.NewLRUCache(cfg.CacheSize),
 		config:      cfg,
 		semaphore:   make(chan struct{}, cfg.MaxClients),
+		varyIndex:   make(map[string]string),
+		ProxyFunc:   newProxyFunc(cfg),
 	}
+
+	s.metrics = newServerMetrics(func() float64 { return float64(len(s.semaphore)) })
+	s.cache.OnPut = func() { s.metrics.cachePutsTotal.Inc() }
+	s.cache.OnEvict = func(key string) { s.metrics.cacheEvictionsTotal.Inc() }
+
+	return s
 }
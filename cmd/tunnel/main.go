@@ -0,0 +1,36 @@
+// Command tunnel runs this module's reverse-tunnel mode, exposing local
+// services through a remote relay. Run with -mode=server on the publicly
+// reachable host and -mode=client (the default) wherever the services
+// themselves are running; see config.LoadTunnelConfig for the full set of
+// flags.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/soumadeep-sarkar/multi-threaded-proxy-server/config"
+	"github.com/soumadeep-sarkar/multi-threaded-proxy-server/internal/tunnel"
+)
+
+func main() {
+	cfg := config.LoadTunnelConfig()
+
+	infoLogger := log.New(os.Stdout, "[INFO] ", log.Ldate|log.Ltime|log.Lshortfile)
+	errorLogger := log.New(os.Stderr, "[ERROR] ", log.Ldate|log.Ltime|log.Lshortfile)
+
+	switch cfg.Mode {
+	case "client":
+		client := tunnel.NewClient(cfg, infoLogger, errorLogger)
+		if err := client.Run(); err != nil {
+			errorLogger.Fatalf("Tunnel-client exited: %v", err)
+		}
+	case "server":
+		server := tunnel.NewServer(cfg, infoLogger, errorLogger)
+		if err := server.Run(); err != nil {
+			errorLogger.Fatalf("Tunnel-server exited: %v", err)
+		}
+	default:
+		errorLogger.Fatalf("Unknown -mode %q: must be \"client\" or \"server\"", cfg.Mode)
+	}
+}
@@ -3,31 +3,61 @@ package config
 import (
 	"flag"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // Config holds all configuration for the proxy server
 type Config struct {
-	Port        string
-	MaxClients  int
-	CacheSize   int
-	LogLevel    string
+	Port               string
+	MaxClients         int
+	CacheSize          int
+	LogLevel           string
+	TunnelAllowedPorts []int
+	CacheMaxEntryBytes int64
+
+	// AdminAddr is the address the admin HTTP server (metrics, cache
+	// inspection, health check) listens on. Empty disables it.
+	AdminAddr string
+
+	// HTTPProxy, HTTPSProxy, and NoProxy configure chaining through a
+	// parent proxy, mirroring net/http's HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+	// environment variable handling.
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
 }
 
 // LoadConfig loads configuration from command line flags and environment variables
 func LoadConfig() *Config {
 	cfg := &Config{
-		Port:       getEnvOrDefault("PROXY_PORT", "9000"),
-		MaxClients: 100,
-		CacheSize:  100,
-		LogLevel:   getEnvOrDefault("LOG_LEVEL", "info"),
+		Port:               getEnvOrDefault("PROXY_PORT", "9000"),
+		MaxClients:         100,
+		CacheSize:          100,
+		LogLevel:           getEnvOrDefault("LOG_LEVEL", "info"),
+		TunnelAllowedPorts: parsePorts(getEnvOrDefault("TUNNEL_ALLOWED_PORTS", "443")),
+		CacheMaxEntryBytes: 10 * 1024 * 1024,
+		AdminAddr:          getEnvOrDefault("ADMIN_ADDR", ":9001"),
+		HTTPProxy:          getEnvOrDefault("HTTP_PROXY", ""),
+		HTTPSProxy:         getEnvOrDefault("HTTPS_PROXY", ""),
+		NoProxy:            getEnvOrDefault("NO_PROXY", ""),
 	}
 
+	var tunnelAllowedPorts string
 	flag.StringVar(&cfg.Port, "port", cfg.Port, "Port to listen on (default: 9000)")
 	flag.IntVar(&cfg.MaxClients, "max-clients", cfg.MaxClients, "Maximum concurrent client connections (default: 100)")
 	flag.IntVar(&cfg.CacheSize, "cache-size", cfg.CacheSize, "LRU cache capacity (default: 100)")
 	flag.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "Log level: info, debug, error (default: info)")
+	flag.StringVar(&tunnelAllowedPorts, "tunnel-allowed-ports", intsToCSV(cfg.TunnelAllowedPorts), "Comma-separated list of ports CONNECT tunnels may target (default: 443)")
+	flag.Int64Var(&cfg.CacheMaxEntryBytes, "cache-max-entry-bytes", cfg.CacheMaxEntryBytes, "Maximum response body size eligible for caching, in bytes (default: 10485760)")
+	flag.StringVar(&cfg.AdminAddr, "admin-addr", cfg.AdminAddr, "Address for the admin HTTP server (metrics, cache inspection, health check); empty disables it (default: :9001)")
+	flag.StringVar(&cfg.HTTPProxy, "http-proxy", cfg.HTTPProxy, "Parent proxy URL for plain HTTP requests (default: $HTTP_PROXY)")
+	flag.StringVar(&cfg.HTTPSProxy, "https-proxy", cfg.HTTPSProxy, "Parent proxy URL for CONNECT tunnels (default: $HTTPS_PROXY)")
+	flag.StringVar(&cfg.NoProxy, "no-proxy", cfg.NoProxy, "Comma-separated hosts, domain suffixes, or CIDRs to bypass the parent proxy for (default: $NO_PROXY)")
 	flag.Parse()
 
+	cfg.TunnelAllowedPorts = parsePorts(tunnelAllowedPorts)
+
 	return cfg
 }
 
@@ -38,3 +68,31 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+// parsePorts converts a comma-separated list of ports into a slice of ints,
+// silently skipping entries that don't parse as valid port numbers.
+func parsePorts(csv string) []int {
+	parts := strings.Split(csv, ",")
+	ports := make([]int, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		port, err := strconv.Atoi(p)
+		if err != nil || port < 1 || port > 65535 {
+			continue
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+// intsToCSV renders a slice of ports back into a comma-separated string,
+// used as the default value shown in -help output.
+func intsToCSV(ports []int) string {
+	strs := make([]string, len(ports))
+	for i, p := range ports {
+		strs[i] = strconv.Itoa(p)
+	}
+	return strings.Join(strs, ",")
+}
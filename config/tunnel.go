@@ -0,0 +1,78 @@
+package config
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServiceMapping maps a local address to the public port it should be
+// exposed on through a tunnel-server.
+type ServiceMapping struct {
+	LocalAddr  string
+	PublicPort int
+}
+
+// TunnelConfig holds configuration for running this binary as a
+// tunnel-client or tunnel-server (see cmd/tunnel), reusing the same flag
+// and environment-variable conventions as Config.
+type TunnelConfig struct {
+	Mode       string
+	ServerAddr string
+	ListenAddr string
+	AuthToken  string
+	Services   []ServiceMapping
+
+	KeepaliveInterval time.Duration
+	KeepaliveTimeout  time.Duration
+}
+
+// LoadTunnelConfig loads tunnel-client/tunnel-server configuration from
+// command line flags and environment variables.
+func LoadTunnelConfig() *TunnelConfig {
+	cfg := &TunnelConfig{
+		Mode:              getEnvOrDefault("TUNNEL_MODE", "client"),
+		ServerAddr:        getEnvOrDefault("TUNNEL_SERVER_ADDR", "127.0.0.1:7000"),
+		ListenAddr:        getEnvOrDefault("TUNNEL_LISTEN_ADDR", ":7000"),
+		AuthToken:         getEnvOrDefault("TUNNEL_AUTH_TOKEN", ""),
+		KeepaliveInterval: 10 * time.Second,
+		KeepaliveTimeout:  30 * time.Second,
+	}
+
+	var services string
+	flag.StringVar(&cfg.Mode, "mode", cfg.Mode, `Tunnel mode: "client" or "server" (default: client)`)
+	flag.StringVar(&cfg.ServerAddr, "server-addr", cfg.ServerAddr, "tunnel-client: address of the tunnel-server's control listener")
+	flag.StringVar(&cfg.ListenAddr, "listen-addr", cfg.ListenAddr, "tunnel-server: address to accept tunnel-client control connections on")
+	flag.StringVar(&cfg.AuthToken, "auth-token", cfg.AuthToken, "Shared secret tunnel-clients authenticate with")
+	flag.StringVar(&services, "services", getEnvOrDefault("TUNNEL_SERVICES", ""), `tunnel-client: comma-separated localAddr=publicPort mappings to register, e.g. "127.0.0.1:8080=9100"`)
+	flag.DurationVar(&cfg.KeepaliveInterval, "keepalive-interval", cfg.KeepaliveInterval, "Control connection keepalive interval")
+	flag.DurationVar(&cfg.KeepaliveTimeout, "keepalive-timeout", cfg.KeepaliveTimeout, "Control connection keepalive timeout")
+	flag.Parse()
+
+	cfg.Services = parseServices(services)
+
+	return cfg
+}
+
+// parseServices parses a comma-separated list of "localAddr=publicPort"
+// mappings, silently skipping entries that don't parse.
+func parseServices(csv string) []ServiceMapping {
+	var mappings []ServiceMapping
+	for _, entry := range strings.Split(csv, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		localAddr, portStr, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		port, err := strconv.Atoi(strings.TrimSpace(portStr))
+		if err != nil {
+			continue
+		}
+		mappings = append(mappings, ServiceMapping{LocalAddr: strings.TrimSpace(localAddr), PublicPort: port})
+	}
+	return mappings
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheableStatusCodes lists the response status codes this proxy is
+// willing to cache, per RFC 7234 section 3.
+var CacheableStatusCodes = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusGone:                 true,
+}
+
+// CacheControl holds the directives relevant to response caching that were
+// parsed out of a Cache-Control header value.
+type CacheControl struct {
+	NoStore    bool
+	NoCache    bool
+	Private    bool
+	MaxAge     int
+	HasMaxAge  bool
+	SMaxAge    int
+	HasSMaxAge bool
+}
+
+// ParseCacheControl parses the directives of a Cache-Control header value.
+// Unknown directives are ignored.
+func ParseCacheControl(value string) CacheControl {
+	var cc CacheControl
+	for _, part := range strings.Split(value, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		arg = strings.Trim(strings.TrimSpace(arg), `"`)
+
+		switch name {
+		case "no-store":
+			cc.NoStore = true
+		case "no-cache":
+			cc.NoCache = true
+		case "private":
+			cc.Private = true
+		case "max-age":
+			if secs, err := strconv.Atoi(arg); err == nil {
+				cc.MaxAge, cc.HasMaxAge = secs, true
+			}
+		case "s-maxage":
+			if secs, err := strconv.Atoi(arg); err == nil {
+				cc.SMaxAge, cc.HasSMaxAge = secs, true
+			}
+		}
+	}
+	return cc
+}
+
+// Expiration computes the absolute time at which a response carrying the
+// given headers becomes stale. respTime is when the proxy received the
+// response, used as a fallback if the response has no Date header. The
+// second return value is false when the response carries no explicit
+// freshness information (no max-age, s-maxage, or Expires), in which case
+// callers should treat the entry as immediately stale.
+func Expiration(header http.Header, respTime time.Time) (time.Time, bool) {
+	cc := ParseCacheControl(header.Get("Cache-Control"))
+
+	base := respTime
+	if date, ok := parseHTTPDate(header.Get("Date")); ok {
+		base = date
+	}
+	age := parseAge(header.Get("Age"))
+
+	switch {
+	case cc.HasSMaxAge:
+		return base.Add(time.Duration(cc.SMaxAge)*time.Second - age), true
+	case cc.HasMaxAge:
+		return base.Add(time.Duration(cc.MaxAge)*time.Second - age), true
+	}
+
+	if expires, ok := parseHTTPDate(header.Get("Expires")); ok {
+		return expires, true
+	}
+
+	return time.Time{}, false
+}
+
+func parseHTTPDate(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func parseAge(value string) time.Duration {
+	secs, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
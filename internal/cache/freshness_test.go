@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseCacheControl(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  CacheControl
+	}{
+		{
+			name: "empty",
+			want: CacheControl{},
+		},
+		{
+			name:  "no-store recognized regardless of casing or spacing",
+			value: " No-Store , max-age=60",
+			want:  CacheControl{NoStore: true, MaxAge: 60, HasMaxAge: true},
+		},
+		{
+			name:  "no-cache and private are independent flags",
+			value: "no-cache, private",
+			want:  CacheControl{NoCache: true, Private: true},
+		},
+		{
+			name:  "max-age and s-maxage both parsed",
+			value: "max-age=60, s-maxage=120",
+			want:  CacheControl{MaxAge: 60, HasMaxAge: true, SMaxAge: 120, HasSMaxAge: true},
+		},
+		{
+			name:  "quoted directive value",
+			value: `max-age="30"`,
+			want:  CacheControl{MaxAge: 30, HasMaxAge: true},
+		},
+		{
+			name:  "unparsable max-age is ignored",
+			value: "max-age=not-a-number",
+			want:  CacheControl{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseCacheControl(tt.value)
+			if got != tt.want {
+				t.Errorf("ParseCacheControl(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	respTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		wantExpiry time.Time
+		wantOK     bool
+	}{
+		{
+			name:   "no freshness information at all",
+			header: http.Header{},
+			wantOK: false,
+		},
+		{
+			name:       "max-age wins over Expires",
+			header:     http.Header{"Cache-Control": {"max-age=60"}, "Expires": {respTime.Add(time.Hour).Format(http.TimeFormat)}},
+			wantExpiry: respTime.Add(60 * time.Second),
+			wantOK:     true,
+		},
+		{
+			name:       "s-maxage takes precedence over max-age",
+			header:     http.Header{"Cache-Control": {"max-age=60, s-maxage=120"}},
+			wantExpiry: respTime.Add(120 * time.Second),
+			wantOK:     true,
+		},
+		{
+			name:       "Expires used when no Cache-Control freshness directive is present",
+			header:     http.Header{"Expires": {respTime.Add(30 * time.Minute).Format(http.TimeFormat)}},
+			wantExpiry: respTime.Add(30 * time.Minute),
+			wantOK:     true,
+		},
+		{
+			name:       "Date header re-bases max-age instead of using respTime",
+			header:     http.Header{"Cache-Control": {"max-age=60"}, "Date": {respTime.Add(-10 * time.Second).Format(http.TimeFormat)}},
+			wantExpiry: respTime.Add(-10 * time.Second).Add(60 * time.Second),
+			wantOK:     true,
+		},
+		{
+			name:       "Age is subtracted from the computed freshness lifetime",
+			header:     http.Header{"Cache-Control": {"max-age=60"}, "Age": {"20"}},
+			wantExpiry: respTime.Add(40 * time.Second),
+			wantOK:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotExpiry, gotOK := Expiration(tt.header, respTime)
+			if gotOK != tt.wantOK {
+				t.Fatalf("Expiration() ok = %v, want %v", gotOK, tt.wantOK)
+			}
+			if gotOK && !gotExpiry.Equal(tt.wantExpiry) {
+				t.Errorf("Expiration() = %v, want %v", gotExpiry, tt.wantExpiry)
+			}
+		})
+	}
+}
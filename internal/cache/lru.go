@@ -3,13 +3,35 @@ package cache
 import (
 	"fmt"
 	"sync"
+	"time"
 )
 
-// ProxyItem represents a cached HTTP response
+// ProxyItem represents a cached HTTP response, along with the metadata
+// needed to decide when it goes stale and how to revalidate it.
 type ProxyItem struct {
-	Status string
-	Header string
-	Body   []byte
+	Method     string
+	StatusCode int
+	Status     string
+	Header     string
+	Body       []byte
+
+	// ETag and LastModified are the validators used to revalidate a stale
+	// entry with the origin via If-None-Match / If-Modified-Since.
+	ETag         string
+	LastModified string
+
+	// Expires is the absolute time after which this entry is stale. A
+	// zero value means the entry has no freshness lifetime and must be
+	// revalidated on every use.
+	Expires time.Time
+
+	// MustRevalidate forces revalidation on every lookup even if Expires
+	// is still in the future, per Cache-Control: no-cache.
+	MustRevalidate bool
+
+	// StoredAt is when this entry was last written, used to report an
+	// entry's age via the admin /cache endpoint.
+	StoredAt time.Time
 }
 
 // node represents a node in the doubly-linked list
@@ -28,6 +50,12 @@ type LRUCache struct {
 	head     *node
 	tail     *node
 	items    map[string]*node
+
+	// OnPut and OnEvict, if set, are invoked whenever an item is stored or
+	// evicted, letting a caller observe cache activity (e.g. for metrics)
+	// without coupling this package to any particular metrics system.
+	OnPut   func()
+	OnEvict func(key string)
 }
 
 // NewLRUCache creates a new LRU cache with the specified capacity
@@ -59,6 +87,10 @@ func (c *LRUCache) Put(key string, value *ProxyItem) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.OnPut != nil {
+		defer c.OnPut()
+	}
+
 	// If key exists, update it
 	if existingNode, ok := c.items[key]; ok {
 		existingNode.value = value
@@ -146,9 +178,14 @@ func (c *LRUCache) evictLRU() {
 	}
 
 	// Remove from map
-	delete(c.items, c.tail.key)
+	key := c.tail.key
+	delete(c.items, key)
 	c.removeNode(c.tail)
 	c.size--
+
+	if c.OnEvict != nil {
+		c.OnEvict(key)
+	}
 }
 
 // Size returns the current number of items in the cache
@@ -163,6 +200,58 @@ func (c *LRUCache) Capacity() int {
 	return c.capacity
 }
 
+// EntryInfo summarizes a cached entry for external inspection (e.g. the
+// admin /cache endpoint) without exposing its body or headers.
+type EntryInfo struct {
+	Key       string
+	SizeBytes int
+	Age       time.Duration
+}
+
+// Entries returns a snapshot of every cached entry's key, body size, and
+// age, ordered from most to least recently used.
+func (c *LRUCache) Entries() []EntryInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entries := make([]EntryInfo, 0, c.size)
+	for n := c.head; n != nil; n = n.next {
+		entries = append(entries, EntryInfo{
+			Key:       n.key,
+			SizeBytes: len(n.value.Body),
+			Age:       now.Sub(n.value.StoredAt),
+		})
+	}
+	return entries
+}
+
+// Purge removes a single key from the cache, reporting whether it was present.
+func (c *LRUCache) Purge(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	delete(c.items, key)
+	c.removeNode(n)
+	c.size--
+	return true
+}
+
+// PurgeAll removes every entry from the cache.
+func (c *LRUCache) PurgeAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]*node)
+	c.head = nil
+	c.tail = nil
+	c.size = 0
+}
+
 // Display prints the cache contents for debugging (not thread-safe, use with caution)
 func (c *LRUCache) Display() {
 	c.mu.Lock()
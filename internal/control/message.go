@@ -0,0 +1,127 @@
+// Package control defines the typed messages exchanged on the tunnel
+// control connection between a tunnel-client and tunnel-server, and the
+// length-prefixed JSON framing used to put them on the wire.
+package control
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Type identifies the kind of control message a frame carries.
+type Type string
+
+const (
+	TypeAuthRequest   Type = "auth_request"
+	TypeRegisterProxy Type = "register_proxy"
+	TypeNewProxyConn  Type = "new_proxy_conn"
+	TypePing          Type = "ping"
+)
+
+// Envelope wraps a typed payload for length-prefixed JSON framing.
+type Envelope struct {
+	Type    Type            `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// AuthRequest is sent by the tunnel-client immediately after connecting,
+// authenticating it to the tunnel-server.
+type AuthRequest struct {
+	Token string `json:"token"`
+}
+
+// RegisterProxy asks the tunnel-server to expose a local service on a
+// public port.
+type RegisterProxy struct {
+	LocalAddr  string `json:"local_addr"`
+	PublicPort int    `json:"public_port"`
+}
+
+// NewProxyConn is sent as the first frame on a freshly opened data stream,
+// telling the tunnel-client which registered service the stream's raw
+// bytes belong to.
+type NewProxyConn struct {
+	PublicPort int `json:"public_port"`
+}
+
+// Ping is exchanged periodically on the control connection as an
+// application-level heartbeat, alongside yamux's own transport keepalive.
+type Ping struct{}
+
+// Encoder writes length-prefixed JSON-encoded control messages.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns an Encoder that writes frames to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes msgType and payload as a single length-prefixed frame.
+func (e *Encoder) Encode(msgType Type, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("control: failed to marshal %s payload: %w", msgType, err)
+	}
+
+	frame, err := json.Marshal(Envelope{Type: msgType, Payload: body})
+	if err != nil {
+		return fmt.Errorf("control: failed to marshal envelope: %w", err)
+	}
+
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(frame)))
+	if _, err := e.w.Write(length[:]); err != nil {
+		return fmt.Errorf("control: failed to write frame length: %w", err)
+	}
+	if _, err := e.w.Write(frame); err != nil {
+		return fmt.Errorf("control: failed to write frame body: %w", err)
+	}
+	return nil
+}
+
+// maxFrameBytes bounds the length prefix Decode will honor. These messages
+// are all small, fixed-shape control payloads, so a generous cap is more
+// than enough; it exists only to stop an unauthenticated peer from forcing
+// a multi-gigabyte allocation with a crafted length prefix.
+const maxFrameBytes = 4 << 20 // 4 MiB
+
+// Decoder reads length-prefixed JSON-encoded control messages.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder returns a Decoder that reads frames from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads and unmarshals the next frame's envelope. It returns an
+// error without reading the frame body if the peer's length prefix
+// exceeds maxFrameBytes, since the caller should close the connection
+// rather than honor an oversized allocation request.
+func (d *Decoder) Decode() (Envelope, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(d.r, length[:]); err != nil {
+		return Envelope{}, err
+	}
+
+	size := binary.BigEndian.Uint32(length[:])
+	if size > maxFrameBytes {
+		return Envelope{}, fmt.Errorf("control: frame of %d bytes exceeds %d byte limit", size, maxFrameBytes)
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(d.r, body); err != nil {
+		return Envelope{}, fmt.Errorf("control: failed to read frame body: %w", err)
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return Envelope{}, fmt.Errorf("control: failed to unmarshal envelope: %w", err)
+	}
+	return envelope, nil
+}
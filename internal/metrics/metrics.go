@@ -0,0 +1,170 @@
+// Package metrics is a minimal Prometheus-compatible metrics registry:
+// just enough counter/gauge/histogram support for the proxy's /metrics
+// endpoint, without pulling in the full client_golang dependency tree.
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Counter is a monotonically increasing metric.
+type Counter struct {
+	name string
+	help string
+
+	mu    sync.Mutex
+	value float64
+}
+
+// Inc increments the counter by 1.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add increments the counter by delta.
+func (c *Counter) Add(delta float64) {
+	c.mu.Lock()
+	c.value += delta
+	c.mu.Unlock()
+}
+
+func (c *Counter) write(b *strings.Builder) {
+	c.mu.Lock()
+	value := c.value
+	c.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n", c.name, c.help)
+	fmt.Fprintf(b, "# TYPE %s counter\n", c.name)
+	fmt.Fprintf(b, "%s %s\n", c.name, formatFloat(value))
+}
+
+// Gauge reports a value that can move up or down. Its value is read on
+// demand via a callback so it always reflects live state (e.g. a channel's
+// current length) rather than a value someone has to remember to update.
+type Gauge struct {
+	name string
+	help string
+	read func() float64
+}
+
+func (g *Gauge) write(b *strings.Builder) {
+	fmt.Fprintf(b, "# HELP %s %s\n", g.name, g.help)
+	fmt.Fprintf(b, "# TYPE %s gauge\n", g.name)
+	fmt.Fprintf(b, "%s %s\n", g.name, formatFloat(g.read()))
+}
+
+// Histogram tracks the distribution of observed values across fixed
+// buckets, rendered in Prometheus's cumulative-bucket style.
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+
+	mu     sync.Mutex
+	counts []uint64
+	sum    float64
+	total  uint64
+}
+
+// Observe records a single value against the histogram's buckets.
+func (h *Histogram) Observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sum += value
+	h.total++
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+}
+
+func (h *Histogram) write(b *strings.Builder) {
+	h.mu.Lock()
+	counts := append([]uint64(nil), h.counts...)
+	sum := h.sum
+	total := h.total
+	h.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n", h.name, h.help)
+	fmt.Fprintf(b, "# TYPE %s histogram\n", h.name)
+	for i, bound := range h.buckets {
+		fmt.Fprintf(b, "%s_bucket{le=\"%s\"} %d\n", h.name, formatFloat(bound), counts[i])
+	}
+	fmt.Fprintf(b, "%s_bucket{le=\"+Inf\"} %d\n", h.name, total)
+	fmt.Fprintf(b, "%s_sum %s\n", h.name, formatFloat(sum))
+	fmt.Fprintf(b, "%s_count %d\n", h.name, total)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Registry collects a set of metrics and renders them in Prometheus text
+// exposition format.
+type Registry struct {
+	mu         sync.Mutex
+	counters   []*Counter
+	gauges     []*Gauge
+	histograms []*Histogram
+}
+
+// NewRegistry creates an empty metrics registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Counter registers and returns a new counter.
+func (r *Registry) Counter(name, help string) *Counter {
+	c := &Counter{name: name, help: help}
+	r.mu.Lock()
+	r.counters = append(r.counters, c)
+	r.mu.Unlock()
+	return c
+}
+
+// Gauge registers a gauge whose value is produced by read whenever the
+// registry is rendered.
+func (r *Registry) Gauge(name, help string, read func() float64) *Gauge {
+	g := &Gauge{name: name, help: help, read: read}
+	r.mu.Lock()
+	r.gauges = append(r.gauges, g)
+	r.mu.Unlock()
+	return g
+}
+
+// Histogram registers and returns a new histogram with the given bucket
+// upper bounds (a final +Inf bucket is implied).
+func (r *Registry) Histogram(name, help string, buckets []float64) *Histogram {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	h := &Histogram{name: name, help: help, buckets: sorted, counts: make([]uint64, len(sorted))}
+	r.mu.Lock()
+	r.histograms = append(r.histograms, h)
+	r.mu.Unlock()
+	return h
+}
+
+// WriteTo renders every registered metric in Prometheus text format.
+func (r *Registry) WriteTo() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	for _, c := range r.counters {
+		c.write(&b)
+	}
+	for _, g := range r.gauges {
+		g.write(&b)
+	}
+	for _, h := range r.histograms {
+		h.write(&b)
+	}
+	return b.String()
+}
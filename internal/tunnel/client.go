@@ -0,0 +1,166 @@
+package tunnel
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/hashicorp/yamux"
+
+	"github.com/soumadeep-sarkar/multi-threaded-proxy-server/config"
+	"github.com/soumadeep-sarkar/multi-threaded-proxy-server/internal/control"
+)
+
+const (
+	dialTimeout    = 10 * time.Second
+	initialBackoff = 1 * time.Second
+	maxBackoff     = 30 * time.Second
+)
+
+// Client dials a tunnel-server over a single multiplexed control
+// connection and registers the configured local services so the
+// tunnel-server can expose them on public ports.
+type Client struct {
+	cfg         *config.TunnelConfig
+	infoLogger  *log.Logger
+	errorLogger *log.Logger
+}
+
+// NewClient creates a tunnel-client for cfg.
+func NewClient(cfg *config.TunnelConfig, infoLogger, errorLogger *log.Logger) *Client {
+	return &Client{cfg: cfg, infoLogger: infoLogger, errorLogger: errorLogger}
+}
+
+// Run connects to the tunnel-server and serves forever, reconnecting with
+// exponential backoff whenever the control session drops.
+func (c *Client) Run() error {
+	backoff := initialBackoff
+
+	for {
+		if err := c.runOnce(); err != nil {
+			c.errorLogger.Printf("Tunnel session ended: %v", err)
+		}
+
+		c.infoLogger.Printf("Reconnecting to tunnel-server in %s", backoff)
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// runOnce establishes one control session with the tunnel-server, runs it
+// until the session ends, and returns the reason it ended.
+func (c *Client) runOnce() error {
+	conn, err := net.DialTimeout("tcp", c.cfg.ServerAddr, dialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to tunnel-server: %w", err)
+	}
+	defer conn.Close()
+
+	session, err := yamux.Client(conn, yamuxConfig(c.cfg))
+	if err != nil {
+		return fmt.Errorf("failed to establish yamux session: %w", err)
+	}
+	defer session.Close()
+
+	controlStream, err := session.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open control stream: %w", err)
+	}
+	defer controlStream.Close()
+
+	enc := control.NewEncoder(controlStream)
+	if err := enc.Encode(control.TypeAuthRequest, control.AuthRequest{Token: c.cfg.AuthToken}); err != nil {
+		return err
+	}
+
+	for _, svc := range c.cfg.Services {
+		reg := control.RegisterProxy{LocalAddr: svc.LocalAddr, PublicPort: svc.PublicPort}
+		if err := enc.Encode(control.TypeRegisterProxy, reg); err != nil {
+			return err
+		}
+		c.infoLogger.Printf("Registered %s -> public port %d", svc.LocalAddr, svc.PublicPort)
+	}
+
+	go c.heartbeat(enc, session)
+
+	for {
+		stream, err := session.Accept()
+		if err != nil {
+			return fmt.Errorf("control session closed: %w", err)
+		}
+		go c.serveStream(stream)
+	}
+}
+
+// heartbeat sends a Ping on the control connection every KeepaliveInterval
+// until the session closes, as an application-level complement to
+// yamux's own transport keepalive.
+func (c *Client) heartbeat(enc *control.Encoder, session *yamux.Session) {
+	ticker := time.NewTicker(c.cfg.KeepaliveInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if session.IsClosed() {
+			return
+		}
+		if err := enc.Encode(control.TypePing, control.Ping{}); err != nil {
+			return
+		}
+	}
+}
+
+// serveStream reads the NewProxyConn header a newly opened data stream
+// starts with, dials the matching local service, and relays the
+// connection's bytes until either side closes.
+func (c *Client) serveStream(stream net.Conn) {
+	dec := control.NewDecoder(stream)
+	envelope, err := dec.Decode()
+	if err != nil {
+		c.errorLogger.Printf("Failed to read proxy conn header: %v", err)
+		stream.Close()
+		return
+	}
+	if envelope.Type != control.TypeNewProxyConn {
+		c.errorLogger.Printf("Unexpected control message %q on data stream", envelope.Type)
+		stream.Close()
+		return
+	}
+
+	var msg control.NewProxyConn
+	if err := json.Unmarshal(envelope.Payload, &msg); err != nil {
+		c.errorLogger.Printf("Failed to decode proxy conn header: %v", err)
+		stream.Close()
+		return
+	}
+
+	localAddr := c.localAddrFor(msg.PublicPort)
+	if localAddr == "" {
+		c.errorLogger.Printf("No registered service for public port %d", msg.PublicPort)
+		stream.Close()
+		return
+	}
+
+	localConn, err := net.DialTimeout("tcp", localAddr, dialTimeout)
+	if err != nil {
+		c.errorLogger.Printf("Failed to dial local service %s: %v", localAddr, err)
+		stream.Close()
+		return
+	}
+
+	relay(stream, localConn)
+}
+
+func (c *Client) localAddrFor(publicPort int) string {
+	for _, svc := range c.cfg.Services {
+		if svc.PublicPort == publicPort {
+			return svc.LocalAddr
+		}
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+package tunnel
+
+import (
+	"io"
+	"net"
+	"sync"
+)
+
+// relay copies bytes bidirectionally between a and b until either side
+// closes, then closes both ends to unblock the other direction's copy.
+func relay(a, b net.Conn) {
+	defer a.Close()
+	defer b.Close()
+
+	var closeOnce sync.Once
+	closeBoth := func() {
+		closeOnce.Do(func() {
+			a.Close()
+			b.Close()
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.Copy(a, b)
+		closeBoth()
+	}()
+
+	io.Copy(b, a)
+	closeBoth()
+
+	<-done
+}
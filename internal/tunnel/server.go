@@ -0,0 +1,158 @@
+package tunnel
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/hashicorp/yamux"
+
+	"github.com/soumadeep-sarkar/multi-threaded-proxy-server/config"
+	"github.com/soumadeep-sarkar/multi-threaded-proxy-server/internal/control"
+)
+
+// Server accepts tunnel-client control connections and, for each service a
+// client registers, listens on the requested public port and relays
+// incoming connections back to the client over the multiplexed session.
+type Server struct {
+	cfg         *config.TunnelConfig
+	infoLogger  *log.Logger
+	errorLogger *log.Logger
+}
+
+// NewServer creates a tunnel-server for cfg.
+func NewServer(cfg *config.TunnelConfig, infoLogger, errorLogger *log.Logger) *Server {
+	return &Server{cfg: cfg, infoLogger: infoLogger, errorLogger: errorLogger}
+}
+
+// Run listens for tunnel-client control connections and serves them until
+// the listener is closed or fails.
+func (s *Server) Run() error {
+	listener, err := net.Listen("tcp", s.cfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for tunnel-clients on %s: %w", s.cfg.ListenAddr, err)
+	}
+	defer listener.Close()
+
+	s.infoLogger.Printf("Tunnel-server listening for control connections on %s", s.cfg.ListenAddr)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return fmt.Errorf("failed to accept tunnel-client connection: %w", err)
+		}
+		go s.handleClient(conn)
+	}
+}
+
+// handleClient runs one tunnel-client's control session: authenticating
+// it, then registering and serving each service it requests.
+func (s *Server) handleClient(conn net.Conn) {
+	defer conn.Close()
+
+	session, err := yamux.Server(conn, yamuxConfig(s.cfg))
+	if err != nil {
+		s.errorLogger.Printf("Failed to establish yamux session: %v", err)
+		return
+	}
+	defer session.Close()
+
+	controlStream, err := session.Accept()
+	if err != nil {
+		s.errorLogger.Printf("Failed to accept control stream: %v", err)
+		return
+	}
+	defer controlStream.Close()
+
+	dec := control.NewDecoder(controlStream)
+
+	envelope, err := dec.Decode()
+	if err != nil || envelope.Type != control.TypeAuthRequest {
+		s.errorLogger.Printf("Tunnel-client did not authenticate: %v", err)
+		return
+	}
+
+	var auth control.AuthRequest
+	if err := json.Unmarshal(envelope.Payload, &auth); err != nil || auth.Token != s.cfg.AuthToken {
+		s.errorLogger.Printf("Rejecting tunnel-client: invalid auth token")
+		return
+	}
+
+	var listeners []net.Listener
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+
+	for {
+		envelope, err := dec.Decode()
+		if err != nil {
+			s.infoLogger.Printf("Tunnel-client control connection closed: %v", err)
+			return
+		}
+
+		switch envelope.Type {
+		case control.TypeRegisterProxy:
+			var reg control.RegisterProxy
+			if err := json.Unmarshal(envelope.Payload, &reg); err != nil {
+				s.errorLogger.Printf("Failed to decode register_proxy: %v", err)
+				continue
+			}
+			listener, err := s.registerProxy(session, reg)
+			if err != nil {
+				s.errorLogger.Printf("Failed to register proxy on port %d: %v", reg.PublicPort, err)
+				continue
+			}
+			listeners = append(listeners, listener)
+		case control.TypePing:
+			// Application-level heartbeat; nothing to do but note the
+			// client is still alive.
+		}
+	}
+}
+
+// registerProxy starts listening on reg's public port and relays every
+// connection it accepts back to the tunnel-client over session.
+func (s *Server) registerProxy(session *yamux.Session, reg control.RegisterProxy) (net.Listener, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", reg.PublicPort))
+	if err != nil {
+		return nil, err
+	}
+
+	s.infoLogger.Printf("Exposing %s on public port %d", reg.LocalAddr, reg.PublicPort)
+
+	go func() {
+		for {
+			publicConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.bridgePublicConn(session, reg, publicConn)
+		}
+	}()
+
+	return listener, nil
+}
+
+// bridgePublicConn opens a new multiplexed stream to the tunnel-client for
+// a public connection and relays bytes between them.
+func (s *Server) bridgePublicConn(session *yamux.Session, reg control.RegisterProxy, publicConn net.Conn) {
+	stream, err := session.Open()
+	if err != nil {
+		s.errorLogger.Printf("Failed to open tunnel stream for port %d: %v", reg.PublicPort, err)
+		publicConn.Close()
+		return
+	}
+
+	enc := control.NewEncoder(stream)
+	if err := enc.Encode(control.TypeNewProxyConn, control.NewProxyConn{PublicPort: reg.PublicPort}); err != nil {
+		s.errorLogger.Printf("Failed to announce new proxy conn: %v", err)
+		stream.Close()
+		publicConn.Close()
+		return
+	}
+
+	relay(stream, publicConn)
+}
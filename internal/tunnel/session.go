@@ -0,0 +1,17 @@
+package tunnel
+
+import (
+	"github.com/hashicorp/yamux"
+
+	"github.com/soumadeep-sarkar/multi-threaded-proxy-server/config"
+)
+
+// yamuxConfig builds the yamux session configuration shared by the
+// tunnel-client and tunnel-server, applying cfg's keepalive settings.
+func yamuxConfig(cfg *config.TunnelConfig) *yamux.Config {
+	c := yamux.DefaultConfig()
+	c.EnableKeepAlive = true
+	c.KeepAliveInterval = cfg.KeepaliveInterval
+	c.ConnectionWriteTimeout = cfg.KeepaliveTimeout
+	return c
+}
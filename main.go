@@ -36,6 +36,10 @@ func (s *ProxyServer) Start() error {
 	s.infoLogger.Printf("🚀 Multi-threaded Proxy Server started on port %s", s.config.Port)
 	s.infoLogger.Printf("📊 Configuration: Max Clients=%d, Cache Size=%d", s.config.MaxClients, s.config.CacheSize)
 
+	if s.config.AdminAddr != "" {
+		go s.startAdminServer()
+	}
+
 	// Handle graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
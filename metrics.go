@@ -0,0 +1,46 @@
+package main
+
+import "github.com/soumadeep-sarkar/multi-threaded-proxy-server/internal/metrics"
+
+// serverMetrics holds the proxy's Prometheus metrics, exposed via the
+// admin /metrics endpoint.
+type serverMetrics struct {
+	registry *metrics.Registry
+
+	requestsTotal       *metrics.Counter
+	cacheHitsTotal      *metrics.Counter
+	cacheMissesTotal    *metrics.Counter
+	cachePutsTotal      *metrics.Counter
+	cacheEvictionsTotal *metrics.Counter
+	upstreamErrorsTotal *metrics.Counter
+	bytesInTotal        *metrics.Counter
+	bytesOutTotal       *metrics.Counter
+
+	upstreamLatencySeconds *metrics.Histogram
+	responseSizeBytes      *metrics.Histogram
+}
+
+// newServerMetrics builds the metrics registry, wiring inFlight as the
+// gauge callback for currently handled connections.
+func newServerMetrics(inFlight func() float64) *serverMetrics {
+	registry := metrics.NewRegistry()
+
+	m := &serverMetrics{
+		registry:            registry,
+		requestsTotal:       registry.Counter("proxy_requests_total", "Total number of client requests handled."),
+		cacheHitsTotal:      registry.Counter("proxy_cache_hits_total", "Total number of cache hits."),
+		cacheMissesTotal:    registry.Counter("proxy_cache_misses_total", "Total number of cache misses."),
+		cachePutsTotal:      registry.Counter("proxy_cache_puts_total", "Total number of cache entries stored."),
+		cacheEvictionsTotal: registry.Counter("proxy_cache_evictions_total", "Total number of cache entries evicted."),
+		upstreamErrorsTotal: registry.Counter("proxy_upstream_errors_total", "Total number of failed upstream requests."),
+		bytesInTotal:        registry.Counter("proxy_bytes_in_total", "Total request bytes read from clients."),
+		bytesOutTotal:       registry.Counter("proxy_bytes_out_total", "Total response bytes written to clients."),
+		upstreamLatencySeconds: registry.Histogram("proxy_upstream_latency_seconds", "Latency of upstream requests, in seconds.",
+			[]float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}),
+		responseSizeBytes: registry.Histogram("proxy_response_size_bytes", "Size of responses returned to clients, in bytes.",
+			[]float64{256, 1024, 16384, 131072, 1048576, 10485760}),
+	}
+	registry.Gauge("proxy_in_flight_connections", "Number of connections currently being handled.", inFlight)
+
+	return m
+}
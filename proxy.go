@@ -6,16 +6,21 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/soumadeep-sarkar/multi-threaded-proxy-server/internal/cache"
 )
 
 const (
-	badGatewayBody = "The target server is unavailable. Please try again later."
-	readTimeout    = 30 * time.Second
-	writeTimeout   = 30 * time.Second
+	badGatewayBody        = "The target server is unavailable. Please try again later."
+	tunnelForbiddenBody   = "Tunneling to this port is not permitted."
+	connectionEstablished = "HTTP/1.1 200 Connection Established\r\n\r\n"
+	readTimeout           = 30 * time.Second
+	writeTimeout          = 30 * time.Second
 )
 
 var badGatewayResponse = fmt.Sprintf("HTTP/1.1 502 Bad Gateway\r\n"+
@@ -25,6 +30,13 @@ var badGatewayResponse = fmt.Sprintf("HTTP/1.1 502 Bad Gateway\r\n"+
 	"\r\n"+
 	badGatewayBody, len(badGatewayBody))
 
+var tunnelForbiddenResponse = fmt.Sprintf("HTTP/1.1 403 Forbidden\r\n"+
+	"Content-Type: text/plain\r\n"+
+	"Content-Length: %d\r\n"+
+	"Connection: close\r\n"+
+	"\r\n"+
+	tunnelForbiddenBody, len(tunnelForbiddenBody))
+
 // handleConnection processes a client connection
 func (s *ProxyServer) handleConnection(conn net.Conn) {
 	// Acquire semaphore to limit concurrent connections
@@ -56,11 +68,33 @@ func (s *ProxyServer) handleConnection(conn net.Conn) {
 
 	// Log request
 	s.infoLogger.Printf("Received %s request for %s%s", request.Method, request.Host, request.URL.Path)
+	s.metrics.requestsTotal.Inc()
+	if request.ContentLength > 0 {
+		s.metrics.bytesInTotal.Add(float64(request.ContentLength))
+	}
+
+	// CONNECT requests establish an HTTPS tunnel and bypass the cache entirely
+	if request.Method == http.MethodConnect {
+		s.handleTunnel(conn, request)
+		return
+	}
+
+	// Only GET/HEAD responses are ever cacheable; everything else is
+	// forwarded straight through.
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		s.forwardUncached(conn, request)
+		return
+	}
+
+	primaryKey := s.generateCacheKey(request)
+	lookupKey := s.varyKeyFor(primaryKey, request.Header)
 
-	// Check cache
-	cacheKey := s.generateCacheKey(request)
-	if cachedItem := s.getCachedResponse(cacheKey); cachedItem != nil {
-		s.sendCachedResponse(conn, cachedItem)
+	item, handled := s.lookupCache(conn, request, primaryKey, lookupKey)
+	if handled {
+		return
+	}
+	if item != nil {
+		s.sendCachedResponse(conn, item)
 		return
 	}
 
@@ -73,34 +107,241 @@ func (s *ProxyServer) handleConnection(conn net.Conn) {
 	}
 	defer response.Body.Close()
 
-	// Prepare and send response
-	statusLine, headers, body := s.prepareResponse(response)
-	responseData := statusLine + headers + "\r\n" + string(body)
+	s.streamAndCache(conn, request, primaryKey, response)
+}
+
+// forwardUncached forwards a non-GET/HEAD request to the target server,
+// streaming the response body straight through without ever consulting or
+// populating the cache.
+func (s *ProxyServer) forwardUncached(conn net.Conn, req *http.Request) {
+	response, err := s.forwardRequest(req)
+	if err != nil {
+		s.errorLogger.Printf("Failed to forward request: %v", err)
+		s.sendErrorResponse(conn)
+		return
+	}
+	defer response.Body.Close()
 
-	if _, err := conn.Write([]byte(responseData)); err != nil {
+	chunked := response.ContentLength < 0
+	if err := writeStatusAndHeaders(conn, response, chunked); err != nil {
 		s.errorLogger.Printf("Failed to send response: %v", err)
 		return
 	}
 
-	// Cache the response
-	s.cacheResponse(cacheKey, statusLine, headers, body)
-	s.infoLogger.Printf("Successfully forwarded and cached response for %s%s", request.Host, request.URL.Path)
+	var body io.Writer = conn
+	if chunked {
+		cw := &chunkedWriter{w: conn}
+		defer cw.Close()
+		body = cw
+	}
+
+	written, err := io.Copy(body, response.Body)
+	if err != nil {
+		s.errorLogger.Printf("Failed to stream response body: %v", err)
+		return
+	}
+	s.metrics.bytesOutTotal.Add(float64(written))
+	s.metrics.responseSizeBytes.Observe(float64(written))
+	s.infoLogger.Printf("Successfully forwarded response for %s%s", req.Host, req.URL.Path)
+}
+
+// streamAndCache writes resp to the client as it arrives from the
+// upstream, tee-ing the body into a size-capped buffer so it can also be
+// committed to the cache once the read completes. Client write failures
+// are swallowed so the cache fill can still finish; the response is only
+// cached if the upstream read completed cleanly and stayed under the
+// configured per-entry size cap.
+func (s *ProxyServer) streamAndCache(conn net.Conn, req *http.Request, primaryKey string, resp *http.Response) {
+	chunked := resp.ContentLength < 0
+	if err := writeStatusAndHeaders(conn, resp, chunked); err != nil {
+		s.errorLogger.Printf("Failed to send response headers: %v", err)
+		// Keep draining upstream below so the cache can still fill.
+	}
+
+	var clientSink io.Writer = &swallowingWriter{w: conn, logger: s.errorLogger}
+	var chunkWriter *chunkedWriter
+	if chunked {
+		chunkWriter = &chunkedWriter{w: clientSink}
+		clientSink = chunkWriter
+	}
+
+	capture := &capturingWriter{limit: s.config.CacheMaxEntryBytes}
+	_, copyErr := io.Copy(io.MultiWriter(clientSink, capture), resp.Body)
+	if chunkWriter != nil {
+		chunkWriter.Close()
+	}
+	s.metrics.bytesOutTotal.Add(float64(capture.written))
+	s.metrics.responseSizeBytes.Observe(float64(capture.written))
+
+	vary := resp.Header.Get("Vary")
+	s.recordVary(primaryKey, vary)
+	finalKey := varyKey(primaryKey, vary, req.Header)
+
+	if copyErr != nil {
+		s.errorLogger.Printf("Upstream read for %s%s ended early: %v", req.Host, req.URL.Path, copyErr)
+		return
+	}
+	if !capture.withinLimit() {
+		s.infoLogger.Printf("Successfully forwarded response for %s%s (exceeds cache-max-entry-bytes, not cached)", req.Host, req.URL.Path)
+		return
+	}
+
+	status := fmt.Sprintf("%s %s\r\n", resp.Proto, resp.Status)
+	headers := cacheHeaders(resp.Header, capture.buf.Len())
+	if s.cacheResponse(finalKey, req, resp, status, headers, capture.buf.Bytes()) {
+		s.infoLogger.Printf("Successfully forwarded and cached response for %s%s", req.Host, req.URL.Path)
+	} else {
+		s.infoLogger.Printf("Successfully forwarded response for %s%s (not cached)", req.Host, req.URL.Path)
+	}
 }
 
-// generateCacheKey creates a unique cache key from the request
+// generateCacheKey creates the primary cache key from the request. The
+// returned key does not yet account for Vary; use varyKeyFor/varyKey for
+// the key actually used to read or write the cache.
 func (s *ProxyServer) generateCacheKey(req *http.Request) string {
 	return fmt.Sprintf("%s:%s:%s", req.Host, req.URL.Path, req.Method)
 }
 
-// getCachedResponse retrieves a response from cache if available
-func (s *ProxyServer) getCachedResponse(key string) *cache.ProxyItem {
+// recordVary remembers the Vary header seen on a response so future
+// lookups for the same resource can fold the right request headers into
+// the cache key before contacting the origin.
+func (s *ProxyServer) recordVary(primaryKey, vary string) {
+	if vary == "" {
+		return
+	}
+	s.varyMu.Lock()
+	s.varyIndex[primaryKey] = vary
+	s.varyMu.Unlock()
+}
+
+// varyKeyFor looks up any previously recorded Vary header for primaryKey
+// and folds the matching request header values into the cache key.
+func (s *ProxyServer) varyKeyFor(primaryKey string, reqHeader http.Header) string {
+	s.varyMu.RLock()
+	vary := s.varyIndex[primaryKey]
+	s.varyMu.RUnlock()
+	return varyKey(primaryKey, vary, reqHeader)
+}
+
+// varyKey augments a primary cache key with the request header values
+// named by a Vary header, so distinct representations of the same
+// resource (e.g. different Accept-Encoding) don't collide in the cache.
+func varyKey(primaryKey, vary string, reqHeader http.Header) string {
+	if vary == "" {
+		return primaryKey
+	}
+
+	var b strings.Builder
+	b.WriteString(primaryKey)
+	for _, name := range strings.Split(vary, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "*" {
+			continue
+		}
+		b.WriteString(":")
+		b.WriteString(name)
+		b.WriteString("=")
+		b.WriteString(reqHeader.Get(name))
+	}
+	return b.String()
+}
+
+// lookupCache returns a usable cached entry for key, revalidating with the
+// origin first if the entry has gone stale. It returns a nil item on a
+// cache miss or when revalidation could not produce a usable entry. If
+// revalidation instead found a changed representation, handled reports
+// true: the response has already been streamed to conn and the caller
+// must not write anything further.
+func (s *ProxyServer) lookupCache(conn net.Conn, req *http.Request, primaryKey, key string) (item *cache.ProxyItem, handled bool) {
 	found, item := s.cache.Get(key)
-	if found && item != nil {
+	if !found || item == nil {
+		s.infoLogger.Printf("Cache MISS for key: %s", key)
+		s.metrics.cacheMissesTotal.Inc()
+		return nil, false
+	}
+
+	if !item.MustRevalidate && !isStale(item) {
 		s.infoLogger.Printf("Cache HIT for key: %s", key)
-		return item
+		s.metrics.cacheHitsTotal.Inc()
+		return item, false
+	}
+
+	s.infoLogger.Printf("Cache entry for %s is stale; revalidating with origin", key)
+	s.metrics.cacheMissesTotal.Inc()
+	return s.revalidate(conn, req, primaryKey, key, item)
+}
+
+// isStale reports whether a cache entry's freshness lifetime has elapsed.
+// An entry with no Expires value is treated as always stale.
+func isStale(item *cache.ProxyItem) bool {
+	return item.Expires.IsZero() || time.Now().After(item.Expires)
+}
+
+// revalidate reissues req with conditional headers derived from item's
+// validators. A 304 response stores a refreshed copy of item in the cache
+// (see refreshedItem) and returns that copy for the caller to send to the
+// client. Any other response is a
+// changed representation: rather than buffering it whole here (the same
+// unbounded-memory risk streamAndCache exists to avoid), it's streamed to
+// conn and re-cached, if cacheable, through that same size-capped tee
+// pipeline, and handled is reported true so the caller does not write a
+// response of its own. Entries with no validators can't be revalidated
+// and are reported as a miss so the caller re-fetches normally.
+func (s *ProxyServer) revalidate(conn net.Conn, req *http.Request, primaryKey, key string, item *cache.ProxyItem) (result *cache.ProxyItem, handled bool) {
+	if item.ETag == "" && item.LastModified == "" {
+		return nil, false
+	}
+
+	condReq := req.Clone(req.Context())
+	if item.ETag != "" {
+		condReq.Header.Set("If-None-Match", item.ETag)
+	}
+	if item.LastModified != "" {
+		condReq.Header.Set("If-Modified-Since", item.LastModified)
+	}
+
+	resp, err := s.forwardRequest(condReq)
+	if err != nil {
+		s.errorLogger.Printf("Revalidation request failed for %s: %v", key, err)
+		return nil, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		io.Copy(io.Discard, resp.Body)
+		refreshed := s.refreshedItem(item, resp.Header)
+		s.cache.Put(key, refreshed)
+		s.infoLogger.Printf("Revalidated cache entry for %s (304 Not Modified)", key)
+		return refreshed, false
 	}
-	s.infoLogger.Printf("Cache MISS for key: %s", key)
-	return nil
+
+	s.infoLogger.Printf("Revalidation for %s received a changed representation; streaming to client", key)
+	s.streamAndCache(conn, req, primaryKey, resp)
+	return nil, true
+}
+
+// refreshedItem returns a copy of item with its validators, freshness
+// lifetime, and served header block updated from a 304 Not Modified
+// response, leaving its cached body untouched. It returns a new value
+// rather than mutating item in place: item is the same pointer
+// LRUCache.Get hands to every caller, so mutating it would race with a
+// concurrent read of the entry (isStale, Entries, sendCachedResponse).
+func (s *ProxyServer) refreshedItem(item *cache.ProxyItem, header http.Header) *cache.ProxyItem {
+	refreshed := *item
+	if etag := header.Get("ETag"); etag != "" {
+		refreshed.ETag = etag
+	}
+	if lm := header.Get("Last-Modified"); lm != "" {
+		refreshed.LastModified = lm
+	}
+
+	decision := evaluateCacheability(item.Method, http.StatusOK, header, len(item.Body), s.config.CacheMaxEntryBytes)
+	refreshed.Expires = decision.expires
+	refreshed.MustRevalidate = decision.mustRevalidate
+	refreshed.StoredAt = time.Now()
+	refreshed.Header = cacheHeaders(header, len(item.Body))
+
+	return &refreshed
 }
 
 // sendCachedResponse sends a cached response to the client
@@ -108,7 +349,10 @@ func (s *ProxyServer) sendCachedResponse(conn net.Conn, item *cache.ProxyItem) {
 	response := item.Status + item.Header + "\r\n" + string(item.Body)
 	if _, err := conn.Write([]byte(response)); err != nil {
 		s.errorLogger.Printf("Failed to send cached response: %v", err)
+		return
 	}
+	s.metrics.bytesOutTotal.Add(float64(len(response)))
+	s.metrics.responseSizeBytes.Observe(float64(len(item.Body)))
 }
 
 // sendErrorResponse sends a 502 Bad Gateway error response
@@ -118,13 +362,183 @@ func (s *ProxyServer) sendErrorResponse(conn net.Conn) {
 	}
 }
 
-// forwardRequest forwards the HTTP request to the target server
+// handleTunnel implements the HTTP CONNECT method, establishing a raw TCP
+// tunnel to the requested host:port and relaying bytes in both directions
+// until either side closes the connection.
+func (s *ProxyServer) handleTunnel(conn net.Conn, req *http.Request) {
+	_, portStr, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		s.errorLogger.Printf("Malformed CONNECT target %q: %v", req.Host, err)
+		s.sendErrorResponse(conn)
+		return
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || !s.isTunnelPortAllowed(port) {
+		s.infoLogger.Printf("Rejecting CONNECT tunnel to %s: port not allowed", req.Host)
+		if _, err := conn.Write([]byte(tunnelForbiddenResponse)); err != nil {
+			s.errorLogger.Printf("Failed to send tunnel forbidden response: %v", err)
+		}
+		return
+	}
+
+	parent, err := s.resolveProxy(req)
+	if err != nil {
+		s.errorLogger.Printf("Failed to resolve upstream proxy for %s: %v", req.Host, err)
+		s.sendErrorResponse(conn)
+		return
+	}
+
+	var targetConn net.Conn
+	if parent != nil {
+		targetConn, err = s.dialViaParentConnect(parent, req.Host)
+	} else {
+		targetConn, err = net.DialTimeout("tcp", req.Host, 10*time.Second)
+	}
+	if err != nil {
+		s.errorLogger.Printf("Failed to connect to tunnel target %s: %v", req.Host, err)
+		s.sendErrorResponse(conn)
+		return
+	}
+
+	if _, err := conn.Write([]byte(connectionEstablished)); err != nil {
+		s.errorLogger.Printf("Failed to confirm tunnel to client: %v", err)
+		targetConn.Close()
+		return
+	}
+
+	s.infoLogger.Printf("Established tunnel to %s", req.Host)
+	s.relay(conn, targetConn)
+}
+
+// isTunnelPortAllowed reports whether CONNECT tunnels may target the given port.
+func (s *ProxyServer) isTunnelPortAllowed(port int) bool {
+	for _, allowed := range s.config.TunnelAllowedPorts {
+		if allowed == port {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveProxy consults s.ProxyFunc, if set, to decide which parent proxy
+// (if any) req should be chained through.
+func (s *ProxyServer) resolveProxy(req *http.Request) (*url.URL, error) {
+	if s.ProxyFunc == nil {
+		return nil, nil
+	}
+	return s.ProxyFunc(req)
+}
+
+// dialViaParentConnect dials parent and issues a CONNECT for target,
+// returning the raw tunnel connection to relay once the parent confirms it.
+func (s *ProxyServer) dialViaParentConnect(parent *url.URL, target string) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", parentDialAddr(parent), 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to upstream proxy: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	connectReq := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", target, target)
+	if _, err := conn.Write([]byte(connectReq)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT to upstream proxy: %w", err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response from upstream proxy: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("upstream proxy refused CONNECT: %s", resp.Status)
+	}
+
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// deadlineConn wraps a net.Conn, refreshing its read/write deadlines on
+// every operation so long-lived tunnels don't die at the idle timeout.
+type deadlineConn struct {
+	net.Conn
+}
+
+func (c *deadlineConn) Read(p []byte) (int, error) {
+	c.Conn.SetReadDeadline(time.Now().Add(readTimeout))
+	return c.Conn.Read(p)
+}
+
+func (c *deadlineConn) Write(p []byte) (int, error) {
+	c.Conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.Conn.Write(p)
+}
+
+// relay copies bytes bidirectionally between client and target until either
+// side closes, then closes both ends to unblock the other goroutine.
+func (s *ProxyServer) relay(client, target net.Conn) {
+	defer target.Close()
+
+	var closeOnce sync.Once
+	closeBoth := func() {
+		closeOnce.Do(func() {
+			client.Close()
+			target.Close()
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := io.Copy(&deadlineConn{target}, &deadlineConn{client}); err != nil {
+			s.errorLogger.Printf("Tunnel copy client->target ended: %v", err)
+		}
+		closeBoth()
+	}()
+
+	if _, err := io.Copy(&deadlineConn{client}, &deadlineConn{target}); err != nil {
+		s.errorLogger.Printf("Tunnel copy target->client ended: %v", err)
+	}
+	closeBoth()
+
+	<-done
+}
+
+// forwardRequest forwards the HTTP request to the target server, or to a
+// parent proxy (chosen via ProxyFunc) if one applies to this request,
+// recording the upstream's latency and error rate as it goes.
 func (s *ProxyServer) forwardRequest(req *http.Request) (*http.Response, error) {
-	// Determine target address
+	start := time.Now()
+	resp, err := s.doForwardRequest(req)
+	if err != nil {
+		s.metrics.upstreamErrorsTotal.Inc()
+		return nil, err
+	}
+	s.metrics.upstreamLatencySeconds.Observe(time.Since(start).Seconds())
+	return resp, nil
+}
+
+// doForwardRequest performs the actual upstream request/response exchange
+// for forwardRequest.
+func (s *ProxyServer) doForwardRequest(req *http.Request) (*http.Response, error) {
+	parent, err := s.resolveProxy(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve upstream proxy: %w", err)
+	}
+
+	// Determine where to dial and how to render the request line: a
+	// parent proxy needs an absolute-URI request line, since it has no
+	// other way to know the origin we want.
 	targetAddr := req.Host
 	if !strings.Contains(targetAddr, ":") {
 		targetAddr += ":80"
 	}
+	requestLine := fmt.Sprintf("%s %s %s\r\n", req.Method, req.URL.Path, req.Proto)
+	if parent != nil {
+		targetAddr = parentDialAddr(parent)
+		requestLine = fmt.Sprintf("%s %s %s\r\n", req.Method, absoluteURI(req), req.Proto)
+	}
 
 	// Establish TCP connection to target server
 	targetConn, err := net.DialTimeout("tcp", targetAddr, 10*time.Second)
@@ -137,7 +551,6 @@ func (s *ProxyServer) forwardRequest(req *http.Request) (*http.Response, error)
 	targetConn.SetDeadline(time.Now().Add(30 * time.Second))
 
 	// Build HTTP request string
-	requestLine := fmt.Sprintf("%s %s %s\r\n", req.Method, req.URL.Path, req.Proto)
 	hostHeader := fmt.Sprintf("Host: %s\r\n", req.Host)
 
 	// Build headers
@@ -170,33 +583,66 @@ func (s *ProxyServer) forwardRequest(req *http.Request) (*http.Response, error)
 	return response, nil
 }
 
-// prepareResponse extracts status, headers, and body from HTTP response
-func (s *ProxyServer) prepareResponse(resp *http.Response) (string, string, []byte) {
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		s.errorLogger.Printf("Failed to read response body: %v", err)
-		body = []byte{}
+// cacheResponse stores the response in the cache if it's cacheable,
+// reporting whether it did so.
+func (s *ProxyServer) cacheResponse(key string, req *http.Request, resp *http.Response, status, headers string, body []byte) bool {
+	item := s.buildCacheItem(req.Method, resp.StatusCode, status, headers, body, resp.Header)
+	if item == nil {
+		return false
 	}
+	s.cache.Put(key, item)
+	return true
+}
 
-	// Build headers string
-	var headers strings.Builder
-	for key, values := range resp.Header {
-		headers.WriteString(fmt.Sprintf("%s: %s\r\n", key, strings.Join(values, ", ")))
+// buildCacheItem evaluates whether a response is cacheable under RFC 7234
+// and, if so, builds the ProxyItem to store for it. It returns nil for
+// responses that must not be cached.
+func (s *ProxyServer) buildCacheItem(method string, statusCode int, status, headers string, body []byte, respHeader http.Header) *cache.ProxyItem {
+	decision := evaluateCacheability(method, statusCode, respHeader, len(body), s.config.CacheMaxEntryBytes)
+	if !decision.cacheable {
+		return nil
 	}
 
-	// Build status line
-	statusLine := fmt.Sprintf("%s %s\r\n", resp.Proto, resp.Status)
+	return &cache.ProxyItem{
+		Method:         method,
+		StatusCode:     statusCode,
+		Status:         status,
+		Header:         headers,
+		Body:           body,
+		ETag:           respHeader.Get("ETag"),
+		LastModified:   respHeader.Get("Last-Modified"),
+		Expires:        decision.expires,
+		MustRevalidate: decision.mustRevalidate,
+		StoredAt:       time.Now(),
+	}
+}
 
-	return statusLine, headers.String(), body
+// cacheDecision captures whether and how a response should be cached.
+type cacheDecision struct {
+	cacheable      bool
+	expires        time.Time
+	mustRevalidate bool
 }
 
-// cacheResponse stores the response in the cache
-func (s *ProxyServer) cacheResponse(key, status, headers string, body []byte) {
-	item := &cache.ProxyItem{
-		Status: status,
-		Header: headers,
-		Body:   body,
+// evaluateCacheability applies the RFC 7234 rules this proxy honors: only
+// GET/HEAD responses with a cacheable status code, under the configured
+// size cap, and not marked no-store/private, are cacheable.
+func evaluateCacheability(method string, statusCode int, header http.Header, bodySize int, maxEntryBytes int64) cacheDecision {
+	if method != http.MethodGet && method != http.MethodHead {
+		return cacheDecision{}
 	}
-	s.cache.Put(key, item)
+	if !cache.CacheableStatusCodes[statusCode] {
+		return cacheDecision{}
+	}
+	if int64(bodySize) > maxEntryBytes {
+		return cacheDecision{}
+	}
+
+	cc := cache.ParseCacheControl(header.Get("Cache-Control"))
+	if cc.NoStore || cc.Private {
+		return cacheDecision{}
+	}
+
+	expires, _ := cache.Expiration(header, time.Now())
+	return cacheDecision{cacheable: true, expires: expires, mustRevalidate: cc.NoCache}
 }
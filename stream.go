@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// writeStatusAndHeaders writes resp's status line and header block to conn.
+// When forceChunked is true, any Content-Length/Transfer-Encoding the
+// upstream sent is replaced with Transfer-Encoding: chunked, since the
+// body will be streamed without a known length ahead of time.
+func writeStatusAndHeaders(conn net.Conn, resp *http.Response, forceChunked bool) error {
+	statusLine := fmt.Sprintf("%s %s\r\n", resp.Proto, resp.Status)
+	headers := formatResponseHeaders(resp.Header, forceChunked)
+	_, err := conn.Write([]byte(statusLine + headers + "\r\n"))
+	return err
+}
+
+// formatResponseHeaders renders resp headers as wire-format header lines.
+// When forceChunked is true, Content-Length is dropped (it's no longer
+// known up front) and a Transfer-Encoding: chunked line is emitted instead
+// of whatever Transfer-Encoding the upstream sent.
+func formatResponseHeaders(header http.Header, forceChunked bool) string {
+	var b strings.Builder
+	for key, values := range header {
+		if forceChunked && (strings.EqualFold(key, "Content-Length") || strings.EqualFold(key, "Transfer-Encoding")) {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s: %s\r\n", key, strings.Join(values, ", ")))
+	}
+	if forceChunked {
+		b.WriteString("Transfer-Encoding: chunked\r\n")
+	}
+	return b.String()
+}
+
+// cacheHeaders renders resp headers for storage in the cache. The full
+// body is buffered by the time an entry is cached, so Transfer-Encoding is
+// dropped in favor of a concrete Content-Length.
+func cacheHeaders(header http.Header, bodyLen int) string {
+	var b strings.Builder
+	for key, values := range header {
+		if strings.EqualFold(key, "Transfer-Encoding") || strings.EqualFold(key, "Content-Length") {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s: %s\r\n", key, strings.Join(values, ", ")))
+	}
+	b.WriteString(fmt.Sprintf("Content-Length: %d\r\n", bodyLen))
+	return b.String()
+}
+
+// chunkedWriter re-chunk-encodes a byte stream for an HTTP/1.1 client. The
+// proxy streams the upstream body as it arrives rather than buffering it
+// in full, so it can no longer compute a Content-Length up front.
+type chunkedWriter struct {
+	w io.Writer
+}
+
+func (c *chunkedWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if _, err := fmt.Fprintf(c.w, "%x\r\n", len(p)); err != nil {
+		return 0, err
+	}
+	if _, err := c.w.Write(p); err != nil {
+		return 0, err
+	}
+	if _, err := c.w.Write([]byte("\r\n")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the terminating zero-length chunk.
+func (c *chunkedWriter) Close() error {
+	_, err := c.w.Write([]byte("0\r\n\r\n"))
+	return err
+}
+
+// swallowingWriter forwards writes to the client connection but never
+// returns an error, so a dead client doesn't abort the upstream copy:
+// the caller keeps draining the response into the cache buffer even after
+// the client has gone away. The first failure is logged once.
+type swallowingWriter struct {
+	w      io.Writer
+	logger *log.Logger
+	failed bool
+}
+
+func (s *swallowingWriter) Write(p []byte) (int, error) {
+	if s.failed {
+		return len(p), nil
+	}
+	if _, err := s.w.Write(p); err != nil {
+		s.failed = true
+		s.logger.Printf("Client write failed, draining upstream for cache only: %v", err)
+	}
+	return len(p), nil
+}
+
+// capturingWriter buffers up to limit bytes of a response body for caching
+// while tracking the true total size written, so the caller can tell a
+// response that fits under the cap from one that was merely truncated.
+type capturingWriter struct {
+	buf     bytes.Buffer
+	limit   int64
+	written int64
+}
+
+func (c *capturingWriter) Write(p []byte) (int, error) {
+	c.written += int64(len(p))
+	if remaining := c.limit - int64(c.buf.Len()); remaining > 0 {
+		if remaining > int64(len(p)) {
+			remaining = int64(len(p))
+		}
+		c.buf.Write(p[:remaining])
+	}
+	return len(p), nil
+}
+
+// withinLimit reports whether the total bytes written stayed under limit,
+// i.e. the buffer holds the complete body rather than a truncated prefix.
+func (c *capturingWriter) withinLimit() bool {
+	return c.written <= c.limit
+}
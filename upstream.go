@@ -0,0 +1,125 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/soumadeep-sarkar/multi-threaded-proxy-server/config"
+)
+
+// ProxyFunc reports the parent proxy the proxy server should dial for req,
+// or a nil URL if req should be sent directly to its origin. It's modeled
+// on http.ProxyFromEnvironment and is pluggable on ProxyServer so callers
+// can override the environment-derived default.
+type ProxyFunc func(*http.Request) (*url.URL, error)
+
+// newProxyFunc builds the default ProxyFunc from cfg, selecting between
+// cfg.HTTPProxy and cfg.HTTPSProxy by request method the same way
+// HTTP_PROXY/HTTPS_PROXY are honored for direct and CONNECT requests,
+// except that cfg.NoProxy hosts always bypass the parent proxy.
+func newProxyFunc(cfg *config.Config) ProxyFunc {
+	noProxy := newNoProxyMatcher(cfg.NoProxy)
+	httpProxy := parseProxyURL(cfg.HTTPProxy)
+	httpsProxy := parseProxyURL(cfg.HTTPSProxy)
+
+	return func(req *http.Request) (*url.URL, error) {
+		if noProxy.match(req.Host) {
+			return nil, nil
+		}
+		if req.Method == http.MethodConnect {
+			return httpsProxy, nil
+		}
+		return httpProxy, nil
+	}
+}
+
+func parseProxyURL(raw string) *url.URL {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+// parentDialAddr returns the host:port to dial to reach parent, defaulting
+// the port from its scheme when one isn't given explicitly.
+func parentDialAddr(parent *url.URL) string {
+	if parent.Port() != "" {
+		return parent.Host
+	}
+	port := "80"
+	if parent.Scheme == "https" {
+		port = "443"
+	}
+	return net.JoinHostPort(parent.Hostname(), port)
+}
+
+// absoluteURI renders req's request target in absolute-form
+// (e.g. "http://example.com/path"), as required when sending a plain HTTP
+// request line to a parent proxy rather than directly to the origin.
+func absoluteURI(req *http.Request) string {
+	if req.URL.IsAbs() {
+		return req.URL.String()
+	}
+	return "http://" + req.Host + req.URL.RequestURI()
+}
+
+// noProxyMatcher decides whether a host should bypass the parent proxy,
+// per a NO_PROXY-style list of exact hosts, ".suffix" domains, and CIDRs.
+type noProxyMatcher struct {
+	exact  map[string]bool
+	suffix []string
+	cidrs  []*net.IPNet
+}
+
+func newNoProxyMatcher(csv string) *noProxyMatcher {
+	m := &noProxyMatcher{exact: make(map[string]bool)}
+
+	for _, entry := range strings.Split(csv, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			m.cidrs = append(m.cidrs, cidr)
+			continue
+		}
+		if strings.HasPrefix(entry, ".") {
+			m.suffix = append(m.suffix, strings.ToLower(entry))
+			continue
+		}
+		m.exact[strings.ToLower(entry)] = true
+	}
+
+	return m
+}
+
+func (m *noProxyMatcher) match(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+
+	if m.exact[host] {
+		return true
+	}
+	for _, suf := range m.suffix {
+		if strings.HasSuffix(host, suf) || host == strings.TrimPrefix(suf, ".") {
+			return true
+		}
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		for _, cidr := range m.cidrs {
+			if cidr.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestNoProxyMatcher(t *testing.T) {
+	m := newNoProxyMatcher("example.com, .internal.example.com, 10.0.0.0/8, localhost")
+
+	tests := []struct {
+		name string
+		host string
+		want bool
+	}{
+		{"exact host match", "example.com", true},
+		{"exact host match is case-insensitive", "Example.COM", true},
+		{"exact match does not cover subdomains", "api.example.com", false},
+		{"suffix entry matches a subdomain", "foo.internal.example.com", true},
+		{"suffix entry matches the bare domain too", "internal.example.com", true},
+		{"suffix entry does not match an unrelated host", "internal.example.com.evil.com", false},
+		{"host:port is split before matching", "localhost:8080", true},
+		{"CIDR entry matches an address inside the block", "10.1.2.3", true},
+		{"CIDR entry rejects an address outside the block", "11.1.2.3", false},
+		{"unrelated host matches nothing", "other.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.match(tt.host); got != tt.want {
+				t.Errorf("match(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoProxyMatcherEmpty(t *testing.T) {
+	m := newNoProxyMatcher("")
+	if m.match("example.com") {
+		t.Error("empty NO_PROXY should match nothing")
+	}
+}